@@ -1,86 +1,95 @@
 package analyzer
 
 import (
-	"fmt"
-	"io"
-	"net/http"
+	"context"
 	"net/url"
 	"strings"
-	"time"
 
-	"golang.org/x/net/html"
+	"github.com/PuerkitoBio/goquery"
+
+	"urlscraper/analyzer/fetcher"
 )
 
+// httpFetcher is the polite fetcher every page fetch goes through. Tests
+// substitute a faster-rate-limited instance; production code can swap it
+// for a custom Fetcher (e.g. one backed by a shared cache) by assignment.
+var httpFetcher fetcher.Fetcher = fetcher.New()
+
+// LinkStatus describes why a probed link was considered inaccessible.
+type LinkStatus struct {
+	URL    string
+	Reason string
+}
+
 // PageAnalysis contains all extracted information about a web page
 type PageAnalysis struct {
-	URL              string
-	Title            string
-	HTMLVersion      string
-	Headings         map[int]int // h1-h6 counts
-	InternalLinks    int
-	ExternalLinks    int
-	InaccessibleLinks int
-	HasLoginForm     bool
-	Error            string
-	StatusCode       int
+	URL                 string
+	Title               string
+	HTMLVersion         string
+	Language            string
+	CanonicalURL        string
+	OpenGraph           map[string]string
+	Headings            map[int]int // h1-h6 counts
+	InternalLinks       int
+	ExternalLinks       int
+	InaccessibleLinks   int // always 0 for a page fetched by CrawlSite; see fetchCrawlPage
+	InaccessibleDetails []LinkStatus
+	SkippedLinks        int // links robots.txt disallowed probing; not counted as broken, just untested
+	SkippedDetails      []LinkStatus
+	HasLoginForm        bool
+	Error               string
+	StatusCode          int
 }
 
-// AnalyzeURL fetches and analyzes a web page
-func AnalyzeURL(urlStr string) *PageAnalysis {
+// AnalyzeURL fetches and analyzes a web page. ctx bounds both the fetch and
+// the link-accessibility probe that follows it, so a caller can cancel a
+// slow analysis instead of waiting it out.
+func AnalyzeURL(ctx context.Context, urlStr string) *PageAnalysis {
+	urlStr = normalizeScheme(urlStr)
 	analysis := &PageAnalysis{
 		URL:      urlStr,
 		Headings: make(map[int]int),
 	}
 
-	// Validate and normalize URL
-	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
-		urlStr = "https://" + urlStr
-	}
-
-	// Fetch the page with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get(urlStr)
+	doc, htmlVersion, status, err := fetchPage(ctx, urlStr)
+	analysis.StatusCode = status
 	if err != nil {
-		analysis.Error = fmt.Sprintf("Failed to fetch URL: %v", err)
-		analysis.StatusCode = 0
+		analysis.Error = err.Error()
 		return analysis
 	}
-	defer resp.Body.Close()
 
-	analysis.StatusCode = resp.StatusCode
-
-	// Check for HTTP error status codes
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		analysis.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
-		return analysis
-	}
-
-	// Read response body with size limit to prevent DoS
-	limitedBody := io.LimitReader(resp.Body, 10*1024*1024) // 10MB limit
-	body, err := io.ReadAll(limitedBody)
-	if err != nil {
-		analysis.Error = fmt.Sprintf("Failed to read response: %v", err)
-		return analysis
-	}
+	populateAnalysis(ctx, analysis, doc, htmlVersion, urlStr, true)
+	return analysis
+}
 
-	// Parse HTML
-	doc, err := html.Parse(strings.NewReader(string(body)))
-	if err != nil {
-		analysis.Error = fmt.Sprintf("Failed to parse HTML: %v", err)
-		return analysis
+// normalizeScheme defaults a bare host/path to https, mirroring what most
+// browsers do when a user omits the scheme.
+func normalizeScheme(urlStr string) string {
+	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
+		return "https://" + urlStr
 	}
+	return urlStr
+}
 
-	// Extract information
-	analysis.HTMLVersion = detectHTMLVersion(string(body))
+// populateAnalysis fills in every extracted field of analysis from an
+// already-fetched document. htmlVersion is passed in rather than
+// recomputed here since fetchPage only has the first kilobyte of the body
+// by the time parsing starts. probeLinks controls whether the link set also
+// gets a live accessibility probe: CrawlSite passes false, since the BFS
+// itself will fetch (or deliberately skip, past MaxDepth/MaxPages) every
+// in-scope link on its own, and a second live request per link here would
+// just double the load a crawl puts on the target host.
+func populateAnalysis(ctx context.Context, analysis *PageAnalysis, doc *goquery.Document, htmlVersion string, pageURL string, probeLinks bool) {
+	analysis.HTMLVersion = htmlVersion
 	analysis.Title = extractTitle(doc)
+	analysis.Language = extractLanguage(doc)
+	analysis.CanonicalURL = extractCanonicalURL(doc)
+	analysis.OpenGraph = extractOpenGraph(doc)
 	analysis.Headings = countHeadings(doc)
-	analysis.InternalLinks, analysis.ExternalLinks, analysis.InaccessibleLinks = analyzeLinks(doc, urlStr)
+	analysis.InternalLinks, analysis.ExternalLinks, analysis.InaccessibleDetails, analysis.SkippedDetails = analyzeLinks(ctx, doc, pageURL, probeLinks)
+	analysis.InaccessibleLinks = len(analysis.InaccessibleDetails)
+	analysis.SkippedLinks = len(analysis.SkippedDetails)
 	analysis.HasLoginForm = detectLoginForm(doc)
-
-	return analysis
 }
 
 // detectHTMLVersion extracts the HTML version from DOCTYPE
@@ -103,136 +112,6 @@ func detectHTMLVersion(html string) string {
 	return "Unknown"
 }
 
-// extractTitle gets the page title
-func extractTitle(doc *html.Node) string {
-	return traverseNode(doc, func(n *html.Node) string {
-		if n.Type == html.ElementNode && n.Data == "title" {
-			if n.FirstChild != nil {
-				return strings.TrimSpace(n.FirstChild.Data)
-			}
-		}
-		return ""
-	})
-}
-
-// countHeadings counts h1-h6 headings
-func countHeadings(doc *html.Node) map[int]int {
-	headings := make(map[int]int)
-	for i := 1; i <= 6; i++ {
-		headings[i] = 0
-	}
-
-	var traverse func(*html.Node)
-	traverse = func(n *html.Node) {
-		if n.Type == html.ElementNode {
-			if len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
-				level := int(n.Data[1] - '0')
-				headings[level]++
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			traverse(c)
-		}
-	}
-
-	traverse(doc)
-	return headings
-}
-
-// analyzeLinks counts internal, external, and inaccessible links
-func analyzeLinks(doc *html.Node, pageURL string) (int, int, int) {
-	internal := 0
-	external := 0
-	inaccessible := 0
-
-	pageHost := extractHost(pageURL)
-
-	var traverse func(*html.Node)
-	traverse = func(n *html.Node) {
-		if n.Type == html.ElementNode {
-			if n.Data == "a" {
-				href := getAttr(n, "href")
-				if href == "" || strings.HasPrefix(href, "#") {
-					inaccessible++
-				} else if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
-					linkHost := extractHost(href)
-					if linkHost == pageHost {
-						internal++
-					} else {
-						external++
-					}
-				} else if strings.HasPrefix(href, "/") || strings.HasPrefix(href, "./") || !strings.Contains(href, "://") {
-					internal++
-				} else {
-					external++
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			traverse(c)
-		}
-	}
-
-	traverse(doc)
-	return internal, external, inaccessible
-}
-
-// detectLoginForm checks if the page contains a login form
-func detectLoginForm(doc *html.Node) bool {
-	var traverse func(*html.Node) bool
-	traverse = func(n *html.Node) bool {
-		if n.Type == html.ElementNode {
-			if n.Data == "form" {
-				// Look for password input in form
-				var hasPassword bool
-				var hasUsername bool
-
-				var checkForm func(*html.Node)
-				checkForm = func(node *html.Node) {
-					if node.Type == html.ElementNode {
-						if node.Data == "input" {
-							typeAttr := getAttr(node, "type")
-							nameAttr := getAttr(node, "name")
-							idAttr := getAttr(node, "id")
-
-							if strings.ToLower(typeAttr) == "password" {
-								hasPassword = true
-							}
-							if strings.ToLower(typeAttr) == "text" || strings.ToLower(typeAttr) == "email" {
-								hasUsername = true
-							}
-							if strings.Contains(strings.ToLower(nameAttr), "user") ||
-								strings.Contains(strings.ToLower(nameAttr), "login") ||
-								strings.Contains(strings.ToLower(nameAttr), "email") ||
-								strings.Contains(strings.ToLower(idAttr), "user") ||
-								strings.Contains(strings.ToLower(idAttr), "login") {
-								hasUsername = true
-							}
-						}
-					}
-					for c := node.FirstChild; c != nil; c = c.NextSibling {
-						checkForm(c)
-					}
-				}
-
-				checkForm(n)
-				if hasPassword && hasUsername {
-					return true
-				}
-			}
-		}
-
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if traverse(c) {
-				return true
-			}
-		}
-		return false
-	}
-
-	return traverse(doc)
-}
-
 // extractHost extracts the hostname from a URL
 func extractHost(urlStr string) string {
 	u, err := url.Parse(urlStr)
@@ -241,28 +120,3 @@ func extractHost(urlStr string) string {
 	}
 	return u.Host
 }
-
-// getAttr gets an attribute value from an HTML node
-func getAttr(n *html.Node, name string) string {
-	for _, attr := range n.Attr {
-		if attr.Key == name {
-			return attr.Val
-		}
-	}
-	return ""
-}
-
-// traverseNode helper for simple node traversal
-func traverseNode(n *html.Node, fn func(*html.Node) string) string {
-	result := fn(n)
-	if result != "" {
-		return result
-	}
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		result = traverseNode(c, fn)
-		if result != "" {
-			return result
-		}
-	}
-	return ""
-}
@@ -1,14 +1,25 @@
 package analyzer
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
-	"golang.org/x/net/html"
+	"github.com/PuerkitoBio/goquery"
+
+	"urlscraper/analyzer/fetcher"
 )
 
+// The package-level httpFetcher defaults to a 500ms per-host rate limit,
+// which would make these tests unbearably slow against local httptest
+// servers. Swap in a fetcher with no rate limiting for the test binary.
+func init() {
+	httpFetcher = fetcher.NewWithRateLimit(0)
+}
+
 // TestDetectHTMLVersion tests HTML version detection
 func TestDetectHTMLVersion(t *testing.T) {
 	tests := []struct {
@@ -75,6 +86,18 @@ func TestExtractTitle(t *testing.T) {
 			html:     "<html><head></head></html>",
 			expected: "",
 		},
+		{
+			name:     "Title with an entity",
+			html:     "<html><head><title>Tom &amp; Jerry</title></head></html>",
+			expected: "Tom & Jerry",
+		},
+		{
+			name: "Title with nested markup",
+			html: "<html><head><title>My <b>Bold</b> Page</title></head></html>",
+			// <title> is RCDATA, so "<b>" is never tokenized as an element -
+			// it comes back as literal text, not a nested tag.
+			expected: "My <b>Bold</b> Page",
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,15 +148,15 @@ func TestCountHeadings(t *testing.T) {
 	}
 }
 
-// TestAnalyzeLinks tests link counting and categorization
-func TestAnalyzeLinks(t *testing.T) {
+// TestClassifyLinks tests link counting and categorization
+func TestClassifyLinks(t *testing.T) {
 	tests := []struct {
-		name                string
-		html                string
-		pageURL             string
-		expectedInternal    int
-		expectedExternal    int
-		expectedInaccessible int
+		name             string
+		html             string
+		pageURL          string
+		expectedInternal int
+		expectedExternal int
+		expectedProbed   int
 	}{
 		{
 			name: "Mix of links",
@@ -148,10 +171,10 @@ func TestAnalyzeLinks(t *testing.T) {
 				</body>
 				</html>
 			`,
-			pageURL:              "https://example.com",
-			expectedInternal:     2,
-			expectedExternal:     1,
-			expectedInaccessible: 2,
+			pageURL:          "https://example.com",
+			expectedInternal: 2,
+			expectedExternal: 1,
+			expectedProbed:   3,
 		},
 		{
 			name: "External link same domain but different scheme",
@@ -162,10 +185,10 @@ func TestAnalyzeLinks(t *testing.T) {
 				</body>
 				</html>
 			`,
-			pageURL:              "https://example.com",
-			expectedInternal:     1,
-			expectedExternal:     0,
-			expectedInaccessible: 0,
+			pageURL:          "https://example.com",
+			expectedInternal: 1,
+			expectedExternal: 0,
+			expectedProbed:   1,
 		},
 		{
 			name: "Relative links",
@@ -177,10 +200,10 @@ func TestAnalyzeLinks(t *testing.T) {
 				</body>
 				</html>
 			`,
-			pageURL:              "https://example.com",
-			expectedInternal:     2,
-			expectedExternal:     0,
-			expectedInaccessible: 0,
+			pageURL:          "https://example.com",
+			expectedInternal: 2,
+			expectedExternal: 0,
+			expectedProbed:   1, // both resolve to https://example.com/page against an empty-path base
 		},
 	}
 
@@ -191,7 +214,7 @@ func TestAnalyzeLinks(t *testing.T) {
 				t.Fatalf("failed to parse HTML: %v", err)
 			}
 
-			internal, external, inaccessible := analyzeLinks(doc, tt.pageURL)
+			internal, external, links := classifyLinks(doc, tt.pageURL)
 
 			if internal != tt.expectedInternal {
 				t.Errorf("internal links: got %d, want %d", internal, tt.expectedInternal)
@@ -199,13 +222,97 @@ func TestAnalyzeLinks(t *testing.T) {
 			if external != tt.expectedExternal {
 				t.Errorf("external links: got %d, want %d", external, tt.expectedExternal)
 			}
-			if inaccessible != tt.expectedInaccessible {
-				t.Errorf("inaccessible links: got %d, want %d", inaccessible, tt.expectedInaccessible)
+			if len(links) != tt.expectedProbed {
+				t.Errorf("probed links: got %d, want %d", len(links), tt.expectedProbed)
 			}
 		})
 	}
 }
 
+// TestProbeLinkAccessibility exercises the concurrent probe pool against
+// httptest servers standing in for healthy, broken, HEAD-averse, and slow
+// endpoints.
+func TestProbeLinkAccessibility(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer broken.Close()
+
+	headAverse := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer headAverse.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	links := []string{ok.URL, broken.URL, headAverse.URL, slow.URL}
+
+	oldTimeout := probeRequestTimeout
+	probeRequestTimeout = 50 * time.Millisecond
+	defer func() { probeRequestTimeout = oldTimeout }()
+
+	inaccessible, _ := probeLinkAccessibility(context.Background(), httpFetcher, links)
+
+	byURL := make(map[string]LinkStatus)
+	for _, r := range inaccessible {
+		byURL[r.URL] = r
+	}
+
+	if _, bad := byURL[ok.URL]; bad {
+		t.Errorf("expected %s to be accessible", ok.URL)
+	}
+	if _, bad := byURL[headAverse.URL]; bad {
+		t.Errorf("expected %s to fall back to GET and succeed", headAverse.URL)
+	}
+	if _, bad := byURL[broken.URL]; !bad {
+		t.Errorf("expected %s to be reported inaccessible", broken.URL)
+	}
+	if _, bad := byURL[slow.URL]; !bad {
+		t.Errorf("expected %s to time out and be reported inaccessible", slow.URL)
+	}
+}
+
+// TestProbeLinkAccessibilitySkipsDisallowed verifies that a link robots.txt
+// forbids probing is reported as skipped, not inaccessible: it was never
+// actually checked, so it shouldn't count as broken.
+func TestProbeLinkAccessibilitySkipsDisallowed(t *testing.T) {
+	var mux http.ServeMux
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	})
+	mux.HandleFunc("/private/page", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	f := fetcher.NewWithRateLimit(0)
+	inaccessible, skipped := probeLinkAccessibility(context.Background(), f, []string{server.URL + "/private/page"})
+
+	if len(inaccessible) != 0 {
+		t.Errorf("expected a disallowed link not to be counted as inaccessible, got %+v", inaccessible)
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("expected exactly 1 skipped link, got %d", len(skipped))
+	}
+	if skipped[0].URL != server.URL+"/private/page" {
+		t.Errorf("skipped link URL: got %q, want %q", skipped[0].URL, server.URL+"/private/page")
+	}
+}
+
 // TestDetectLoginForm tests login form detection
 func TestDetectLoginForm(t *testing.T) {
 	tests := []struct {
@@ -338,7 +445,7 @@ func TestAnalyzeURLWithMockServer(t *testing.T) {
 			defer server.Close()
 
 			// Analyze the mock server URL
-			result := AnalyzeURL(server.URL)
+			result := AnalyzeURL(context.Background(), server.URL)
 
 			if tt.shouldError {
 				if result.Error == "" {
@@ -359,7 +466,7 @@ func TestAnalyzeURLWithMockServer(t *testing.T) {
 // TestAnalyzeURLWithInvalidURL tests handling of invalid URLs
 func TestAnalyzeURLWithInvalidURL(t *testing.T) {
 	// This test uses a deliberately non-existent domain
-	result := AnalyzeURL("https://definitely-invalid-domain-that-does-not-exist-12345.com")
+	result := AnalyzeURL(context.Background(), "https://definitely-invalid-domain-that-does-not-exist-12345.com")
 
 	if result.Error == "" {
 		t.Errorf("expected error for invalid URL, but got none")
@@ -372,6 +479,14 @@ func TestAnalyzeURLWithInvalidURL(t *testing.T) {
 
 // TestCompletePageAnalysis tests a comprehensive page analysis
 func TestCompletePageAnalysis(t *testing.T) {
+	// A separate host stands in for the "external" link so the accessibility
+	// probe has a deterministic, local target instead of reaching out to the
+	// real internet.
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer external.Close()
+
 	htmlContent := `
 		<!DOCTYPE html>
 		<html>
@@ -385,7 +500,7 @@ func TestCompletePageAnalysis(t *testing.T) {
 			<h2>Section 1</h2>
 			<p>Content 1</p>
 			<a href="/">Home</a>
-			<a href="https://external.com">External</a>
+			<a href="` + external.URL + `">External</a>
 			<a href="#">Anchor</a>
 
 			<h2>Section 2</h2>
@@ -406,7 +521,7 @@ func TestCompletePageAnalysis(t *testing.T) {
 	}))
 	defer server.Close()
 
-	result := AnalyzeURL(server.URL)
+	result := AnalyzeURL(context.Background(), server.URL)
 
 	// Verify all expected values
 	if result.Error != "" {
@@ -447,8 +562,8 @@ func TestCompletePageAnalysis(t *testing.T) {
 }
 
 // Helper function to parse HTML for testing
-func parseHTML(htmlStr string) (*html.Node, error) {
-	return html.Parse(strings.NewReader(htmlStr))
+func parseHTML(htmlStr string) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(strings.NewReader(htmlStr))
 }
 
 // Benchmark tests
@@ -472,7 +587,7 @@ func BenchmarkAnalyzeURL(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		AnalyzeURL(server.URL)
+		AnalyzeURL(context.Background(), server.URL)
 	}
 }
 
@@ -0,0 +1,257 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Defaults applied when a CrawlOptions field is left at its zero value.
+const (
+	defaultMaxDepth    = 2
+	defaultMaxPages    = 50
+	defaultConcurrency = 5
+)
+
+// CrawlOptions configures a CrawlSite run.
+type CrawlOptions struct {
+	MaxDepth     int
+	MaxPages     int
+	SameHostOnly bool
+	Concurrency  int
+
+	// Progress, if set, is called after every page is recorded with the
+	// number of pages fetched so far and the number of distinct URLs seen
+	// (fetched, still queued, or dropped from the frontier once MaxPages
+	// left no room for them). Callers use it to report crawl progress
+	// without polling the SiteReport mid-run.
+	Progress func(fetched, seen int)
+}
+
+// SiteReport aggregates the pages visited during a crawl.
+type SiteReport struct {
+	SeedURL          string
+	Pages            []*PageAnalysis
+	Edges            map[string][]string // page URL -> links found on that page
+	TotalPages       int
+	TotalBrokenLinks int // always 0: crawled pages skip the per-link probe, see fetchCrawlPage
+	LoginFormsFound  int
+	HeadingHistogram map[int]int // h1-h6 counts, summed across every page
+}
+
+// crawledPage is the intermediate result of fetching and analyzing one page
+// during a crawl, before its links have been filtered and enqueued.
+type crawledPage struct {
+	url      string
+	analysis *PageAnalysis
+	links    []string
+}
+
+// CrawlSite turns the single-page analyzer into a small breadth-first
+// crawler: starting at seedURL, it fetches pages N at a time, extracts and
+// resolves every link, and enqueues unseen ones until MaxDepth or MaxPages
+// is reached. ctx bounds the whole crawl; cancelling it stops new fetches
+// and CrawlSite returns whatever it has collected so far.
+func CrawlSite(ctx context.Context, seedURL string, opts CrawlOptions) *SiteReport {
+	opts = applyCrawlDefaults(opts)
+	seedURL = normalizeScheme(seedURL)
+	seedHost := extractHost(seedURL)
+
+	report := &SiteReport{
+		SeedURL:          seedURL,
+		Edges:            make(map[string][]string),
+		HeadingHistogram: make(map[int]int),
+	}
+
+	seedNorm, err := normalizeURL(seedURL)
+	if err != nil {
+		return report
+	}
+
+	visited := map[string]bool{seedNorm: true}
+	frontier := []string{seedURL}
+
+	for depth := 0; depth <= opts.MaxDepth && len(frontier) > 0 && len(report.Pages) < opts.MaxPages && ctx.Err() == nil; depth++ {
+		var next []string
+
+		remaining := opts.MaxPages - len(report.Pages)
+		if remaining < len(frontier) {
+			frontier = frontier[:remaining]
+		}
+
+		for _, page := range fetchFrontier(ctx, frontier, opts.Concurrency) {
+			if len(report.Pages) >= opts.MaxPages {
+				break
+			}
+			recordPage(report, page)
+			if opts.Progress != nil {
+				opts.Progress(len(report.Pages), len(visited))
+			}
+
+			if depth == opts.MaxDepth {
+				continue
+			}
+			for _, link := range page.links {
+				if opts.SameHostOnly && !strings.EqualFold(extractHost(link), seedHost) {
+					continue
+				}
+				norm, err := normalizeURL(link)
+				if err != nil || visited[norm] {
+					continue
+				}
+				visited[norm] = true
+				next = append(next, link)
+			}
+		}
+
+		frontier = next
+	}
+
+	report.TotalPages = len(report.Pages)
+	return report
+}
+
+// recordPage folds one crawled page into the aggregated report.
+func recordPage(report *SiteReport, page *crawledPage) {
+	report.Pages = append(report.Pages, page.analysis)
+	report.Edges[page.url] = page.links
+	report.TotalBrokenLinks += page.analysis.InaccessibleLinks
+	if page.analysis.HasLoginForm {
+		report.LoginFormsFound++
+	}
+	for level, count := range page.analysis.Headings {
+		report.HeadingHistogram[level] += count
+	}
+}
+
+// fetchFrontier fetches and analyzes every URL in the frontier through a
+// bounded pool of concurrency workers, stopping early if ctx is cancelled.
+func fetchFrontier(ctx context.Context, urls []string, concurrency int) []*crawledPage {
+	if concurrency > len(urls) {
+		concurrency = len(urls)
+	}
+
+	jobs := make(chan string)
+	results := make(chan *crawledPage, len(urls))
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				results <- fetchCrawlPage(ctx, u)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, u := range urls {
+			select {
+			case jobs <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pages := make([]*crawledPage, 0, len(urls))
+	for page := range results {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// fetchCrawlPage fetches and analyzes a single page, additionally resolving
+// its links so the caller can decide what to enqueue next. It skips the
+// per-link accessibility probe AnalyzeURL otherwise runs: during a crawl the
+// BFS fetches (or intentionally skips) every in-scope link itself, so a
+// second live request per link would just double the load on the host.
+func fetchCrawlPage(ctx context.Context, urlStr string) *crawledPage {
+	analysis := &PageAnalysis{URL: urlStr, Headings: make(map[int]int)}
+
+	doc, htmlVersion, status, err := fetchPage(ctx, urlStr)
+	analysis.StatusCode = status
+	if err != nil {
+		analysis.Error = err.Error()
+		return &crawledPage{url: urlStr, analysis: analysis}
+	}
+
+	populateAnalysis(ctx, analysis, doc, htmlVersion, urlStr, false)
+
+	base, err := url.Parse(urlStr)
+	if err != nil {
+		return &crawledPage{url: urlStr, analysis: analysis}
+	}
+
+	return &crawledPage{url: urlStr, analysis: analysis, links: resolveHrefs(doc, base)}
+}
+
+func applyCrawlDefaults(opts CrawlOptions) CrawlOptions {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = defaultMaxDepth
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = defaultMaxPages
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	return opts
+}
+
+// normalizeURL canonicalizes a URL for the visited-set: lowercase host,
+// default ports stripped, fragment dropped, and query keys sorted so that
+// equivalent URLs with reordered parameters dedupe correctly.
+func normalizeURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	u.Fragment = ""
+	u.Host = normalizeHost(u)
+	if u.RawQuery != "" {
+		u.RawQuery = sortedQuery(u.Query())
+	}
+
+	return u.String(), nil
+}
+
+func normalizeHost(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port == "" {
+		return host
+	}
+	return host + ":" + port
+}
+
+func sortedQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
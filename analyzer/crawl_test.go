@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestNormalizeURL tests the visited-set canonicalization.
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{"default https port stripped", "https://Example.com:443/page", "https://example.com/page"},
+		{"default http port stripped", "http://Example.com:80/page", "http://example.com/page"},
+		{"fragment dropped", "https://example.com/page#section", "https://example.com/page"},
+		{"query keys reordered", "https://example.com/page?b=2&a=1", "https://example.com/page?a=1&b=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normA, err := normalizeURL(tt.a)
+			if err != nil {
+				t.Fatalf("normalizeURL(%q): %v", tt.a, err)
+			}
+			normB, err := normalizeURL(tt.b)
+			if err != nil {
+				t.Fatalf("normalizeURL(%q): %v", tt.b, err)
+			}
+			if normA != normB {
+				t.Errorf("expected %q and %q to normalize the same, got %q vs %q", tt.a, tt.b, normA, normB)
+			}
+		})
+	}
+}
+
+// TestCrawlSiteRespectsDepthAndDedup crawls a small linked-page fixture and
+// checks that the BFS stays within MaxDepth and never revisits a page.
+func TestCrawlSiteRespectsDepthAndDedup(t *testing.T) {
+	var mux http.ServeMux
+	var server *httptest.Server
+
+	visits := make(map[string]int)
+	var mu sync.Mutex
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visits[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte(`<html><body><a href="/a">A</a><a href="/b">B</a></body></html>`))
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visits[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte(`<html><body><a href="/">Home</a><a href="/c">C</a></body></html>`))
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visits[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte(`<html><body><a href="/">Home</a></body></html>`))
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visits[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte(`<html><body>Leaf page</body></html>`))
+	})
+
+	server = httptest.NewServer(&mux)
+	defer server.Close()
+
+	report := CrawlSite(context.Background(), server.URL, CrawlOptions{MaxDepth: 1, MaxPages: 10, SameHostOnly: true, Concurrency: 2})
+
+	if report.TotalPages != 3 {
+		t.Fatalf("expected 3 pages visited (depth 0 and 1), got %d", report.TotalPages)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, count := range visits {
+		if count != 1 {
+			t.Errorf("path %s fetched %d times, want exactly 1", path, count)
+		}
+	}
+	if _, ok := visits["/c"]; ok {
+		t.Errorf("expected /c to be beyond MaxDepth and never fetched")
+	}
+}
+
+// TestCrawlSiteMaxPages caps the number of pages visited even when more are
+// discoverable.
+func TestCrawlSiteMaxPages(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a></body></html>`))
+	})
+	for _, p := range []string{"/a", "/b", "/c"} {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html><body>Leaf</body></html>`))
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report := CrawlSite(context.Background(), server.URL, CrawlOptions{MaxDepth: 3, MaxPages: 2, SameHostOnly: true, Concurrency: 2})
+
+	if report.TotalPages != 2 {
+		t.Errorf("expected crawl to stop at MaxPages=2, got %d", report.TotalPages)
+	}
+}
+
+// TestCrawlSiteDoesNotFetchPastMaxPages checks that a frontier larger than
+// the remaining page budget is trimmed before being dispatched, not fetched
+// in full and discarded afterward - the whole point of MaxPages is to bound
+// the actual request volume against the target host.
+func TestCrawlSiteDoesNotFetchPastMaxPages(t *testing.T) {
+	var mu sync.Mutex
+	hits := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.Write([]byte(`<html><body><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a><a href="/d">D</a></body></html>`))
+	})
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits++
+			mu.Unlock()
+			w.Write([]byte(`<html><body>Leaf</body></html>`))
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	report := CrawlSite(context.Background(), server.URL, CrawlOptions{MaxDepth: 3, MaxPages: 2, SameHostOnly: true, Concurrency: 4})
+
+	if report.TotalPages != 2 {
+		t.Errorf("expected crawl to stop at MaxPages=2, got %d", report.TotalPages)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hits != 2 {
+		t.Errorf("expected exactly 2 requests for a MaxPages=2 crawl, got %d - the frontier was fetched past the page budget", hits)
+	}
+}
@@ -0,0 +1,204 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// extractTitle gets the page title. goquery's Text() concatenates every
+// descendant text node and decodes entities, so titles with nested markup
+// or HTML entities no longer come out truncated.
+func extractTitle(doc *goquery.Document) string {
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+// extractLanguage reads the page's declared language from <html lang>.
+func extractLanguage(doc *goquery.Document) string {
+	lang, _ := doc.Find("html").First().Attr("lang")
+	return lang
+}
+
+// extractCanonicalURL reads <link rel="canonical">.
+func extractCanonicalURL(doc *goquery.Document) string {
+	href, _ := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	return href
+}
+
+// extractOpenGraph collects every <meta property="og:*"> tag, keyed without
+// the "og:" prefix (e.g. "title", "image").
+func extractOpenGraph(doc *goquery.Document) map[string]string {
+	og := make(map[string]string)
+	doc.Find(`meta[property^="og:"]`).Each(func(_ int, s *goquery.Selection) {
+		property, _ := s.Attr("property")
+		content, _ := s.Attr("content")
+		og[strings.TrimPrefix(property, "og:")] = content
+	})
+	return og
+}
+
+// countHeadings counts h1-h6 headings
+func countHeadings(doc *goquery.Document) map[int]int {
+	headings := make(map[int]int)
+	for i := 1; i <= 6; i++ {
+		headings[i] = 0
+	}
+
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		level := int(goquery.NodeName(s)[1] - '0')
+		headings[level]++
+	})
+
+	return headings
+}
+
+// analyzeLinks classifies every <a href> as internal or external and, when
+// probeLinks is set, probes the resolved set for real-world accessibility
+// through the same polite fetcher page fetches use. skipped holds links
+// robots.txt told us not to check, which is distinct from inaccessible: an
+// untested link isn't a broken one.
+func analyzeLinks(ctx context.Context, doc *goquery.Document, pageURL string, probeLinks bool) (internal, external int, inaccessible, skipped []LinkStatus) {
+	internal, external, links := classifyLinks(doc, pageURL)
+	if !probeLinks {
+		return internal, external, nil, nil
+	}
+	inaccessible, skipped = probeLinkAccessibility(ctx, httpFetcher, links)
+	return internal, external, inaccessible, skipped
+}
+
+// classifyLinks walks the document counting internal vs external links and
+// returns the deduplicated, absolute set of hrefs worth probing. Hrefs that
+// don't point anywhere (empty or a bare "#") are skipped entirely rather
+// than counted as broken, since probing is what actually tells us that now.
+func classifyLinks(doc *goquery.Document, pageURL string) (int, int, []string) {
+	internal := 0
+	external := 0
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+
+	seen := make(map[string]bool)
+	var links []string
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if href == "" || href == "#" {
+			return
+		}
+		ref, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+
+		resolved := base.ResolveReference(ref)
+		if strings.EqualFold(resolved.Host, base.Host) {
+			internal++
+		} else {
+			external++
+		}
+
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return
+		}
+		key := resolved.String()
+		if !seen[key] {
+			seen[key] = true
+			links = append(links, key)
+		}
+	})
+
+	return internal, external, links
+}
+
+// resolveHrefs returns the deduplicated, absolute http(s) links found on a
+// page, regardless of host - callers decide what to do with each host. It's
+// the crawler's counterpart to classifyLinks, which additionally classifies
+// links by host for the single-page report.
+func resolveHrefs(doc *goquery.Document, base *url.URL) []string {
+	seen := make(map[string]bool)
+	var links []string
+
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if href == "" || href == "#" {
+			return
+		}
+		ref, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return
+		}
+		key := resolved.String()
+		if !seen[key] {
+			seen[key] = true
+			links = append(links, key)
+		}
+	})
+
+	return links
+}
+
+// detectLoginForm checks if the page contains a login form. A form is only
+// a candidate if it has a password input; from there we look for stronger
+// signals (a login-ish action, autocomplete="current-password", an
+// ARIA-labeled login role) before falling back to the old name/id heuristic
+// for a username-like field.
+func detectLoginForm(doc *goquery.Document) bool {
+	found := false
+	doc.Find(`form:has(input[type="password"])`).EachWithBreak(func(_ int, form *goquery.Selection) bool {
+		if formLooksLikeLogin(form) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func formLooksLikeLogin(form *goquery.Selection) bool {
+	if action, ok := form.Attr("action"); ok && strings.Contains(strings.ToLower(action), "login") {
+		return true
+	}
+	if form.Find(`input[autocomplete="current-password"]`).Length() > 0 {
+		return true
+	}
+	if role, ok := form.Attr("role"); ok && strings.EqualFold(role, "form") {
+		if label, ok := form.Attr("aria-label"); ok {
+			low := strings.ToLower(label)
+			if strings.Contains(low, "login") || strings.Contains(low, "sign in") {
+				return true
+			}
+		}
+	}
+
+	hasUsername := false
+	form.Find("input").EachWithBreak(func(_ int, input *goquery.Selection) bool {
+		typeAttr, _ := input.Attr("type")
+		nameAttr, _ := input.Attr("name")
+		idAttr, _ := input.Attr("id")
+		autocomplete, _ := input.Attr("autocomplete")
+
+		t := strings.ToLower(typeAttr)
+		if t == "text" || t == "email" ||
+			strings.Contains(strings.ToLower(nameAttr), "user") ||
+			strings.Contains(strings.ToLower(nameAttr), "login") ||
+			strings.Contains(strings.ToLower(nameAttr), "email") ||
+			strings.Contains(strings.ToLower(idAttr), "user") ||
+			strings.Contains(strings.ToLower(idAttr), "login") ||
+			strings.EqualFold(autocomplete, "username") {
+			hasUsername = true
+			return false
+		}
+		return true
+	})
+
+	return hasUsername
+}
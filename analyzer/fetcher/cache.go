@@ -0,0 +1,152 @@
+package fetcher
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxCachedBodyBytes bounds how much of a cacheable response reconcile will
+// buffer for conditional-request replay. It's deliberately far below the
+// analyzer's own maxBodyBytes: caching exists to save a round trip on small,
+// frequently-revalidated resources (robots.txt-sized pages), not to shadow
+// the streaming size/time budget stream.go applies to full pages. A body
+// larger than this simply isn't cached - see cachingBody.
+const maxCachedBodyBytes = 256 * 1024
+
+// cacheEntry is a previously seen response, kept so later requests to the
+// same URL can be made conditional.
+type cacheEntry struct {
+	etag         string
+	lastModified string
+	status       int
+	header       http.Header
+	body         []byte
+}
+
+// responseCache is an in-memory conditional-request cache keyed by URL.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]*cacheEntry)}
+}
+
+// applyConditional attaches If-None-Match/If-Modified-Since headers if we
+// have a cached entry for this request's URL.
+func (c *responseCache) applyConditional(req *http.Request) {
+	c.mu.Lock()
+	entry, ok := c.entries[req.URL.String()]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+}
+
+// reconcile updates the cache from resp and, on a 304, replays the
+// previously cached body and headers so the caller sees a normal 200.
+func (c *responseCache) reconcile(key string, resp *http.Response) *http.Response {
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if !ok {
+			return resp
+		}
+		return replay(resp, entry)
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp
+	}
+
+	resp.Body = &cachingBody{
+		rc:    resp.Body,
+		cache: c,
+		key:   key,
+		entry: &cacheEntry{
+			etag:         etag,
+			lastModified: lastModified,
+			status:       resp.StatusCode,
+			header:       resp.Header.Clone(),
+		},
+	}
+	return resp
+}
+
+func replay(resp *http.Response, entry *cacheEntry) *http.Response {
+	resp.StatusCode = entry.status
+	resp.Header = entry.header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(entry.body))
+	return resp
+}
+
+// cachingBody streams straight through to the underlying response body -
+// whoever reads it (stream.go's size/time-limited reader, typically) sees
+// the same live connection it would without a cache - while mirroring the
+// bytes into a bounded buffer on the side. Once the body's fully drained
+// without exceeding maxCachedBodyBytes, the buffered copy is committed to
+// the cache for the next request's conditional replay. A body that
+// overflows the cap is simply never cached; the caller is unaffected either
+// way since its own reads are never altered or truncated.
+type cachingBody struct {
+	rc         io.ReadCloser
+	cache      *responseCache
+	key        string
+	entry      *cacheEntry
+	buf        bytes.Buffer
+	overflowed bool
+	eofSeen    bool
+	committed  bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 && !b.overflowed {
+		if b.buf.Len()+n > maxCachedBodyBytes {
+			b.overflowed = true
+			b.buf.Reset()
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		b.eofSeen = true
+		b.commit()
+	}
+	return n, err
+}
+
+// Close never commits on its own: a caller that closes the body early
+// (probe.go's ranged-GET probe only reads the first kilobyte, for example)
+// must not cache that truncated prefix as if it were the complete response.
+// Only a Read that actually reaches io.EOF proves the body was drained.
+func (b *cachingBody) Close() error {
+	return b.rc.Close()
+}
+
+func (b *cachingBody) commit() {
+	if b.committed || b.overflowed || !b.eofSeen {
+		return
+	}
+	b.committed = true
+	b.entry.body = append([]byte(nil), b.buf.Bytes()...)
+
+	b.cache.mu.Lock()
+	b.cache.entries[b.key] = b.entry
+	b.cache.mu.Unlock()
+}
@@ -0,0 +1,207 @@
+package fetcher
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRobotsDisallow verifies that a path disallowed for our user agent (or
+// for "*") is rejected before any request reaches the handler.
+func TestRobotsDisallow(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	f := NewWithRateLimit(0)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/private/page", nil)
+	if _, err := f.Do(req); err == nil {
+		t.Fatal("expected disallowed path to be rejected")
+	}
+	if hits != 0 {
+		t.Errorf("handler should not have been reached for a disallowed path, got %d hits", hits)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/public/page", nil)
+	if _, err := f.Do(req); err != nil {
+		t.Errorf("expected allowed path to succeed, got %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("expected exactly 1 hit for the allowed path, got %d", hits)
+	}
+}
+
+// TestRobotsDisallowMatchesProductToken verifies that a group addressed by
+// our short product token ("urlscraper"), not the full User-Agent header
+// value, is recognized and applied - real robots.txt files only ever name
+// crawlers by that token.
+func TestRobotsDisallowMatchesProductToken(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: urlscraper\nDisallow: /private\n"))
+			return
+		}
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	f := NewWithRateLimit(0)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/private/page", nil)
+	if _, err := f.Do(req); err == nil {
+		t.Fatal("expected disallowed path to be rejected")
+	}
+	if hits != 0 {
+		t.Errorf("handler should not have been reached for a disallowed path, got %d hits", hits)
+	}
+}
+
+// TestConditionalGETReplaysCachedBody verifies that a 304 response is turned
+// back into the originally cached 200 body and headers.
+func TestConditionalGETReplaysCachedBody(t *testing.T) {
+	const body = "cached content"
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	f := NewWithRateLimit(0)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/page", nil)
+	resp, err := f.Do(req)
+	if err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+	// Drain to EOF, the way stream.go's size-limited reader eventually does
+	// for a real page fetch: only a fully-drained body gets cached.
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("failed to read first response body: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/page", nil)
+	resp, err = f.Do(req)
+	if err != nil {
+		t.Fatalf("second request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected replayed status 200, got %d", resp.StatusCode)
+	}
+	if requests != 2 {
+		t.Errorf("expected server to see 2 requests, got %d", requests)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read replayed response body: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("replayed body: got %q, want %q", got, body)
+	}
+}
+
+// TestEarlyCloseDoesNotCacheTruncatedBody verifies that closing a response
+// body before it's fully read - as probe.go's ranged-GET fallback does -
+// never caches the partial read as if it were the complete response.
+func TestEarlyCloseDoesNotCacheTruncatedBody(t *testing.T) {
+	const body = "this is the full, ninety-plus byte body that a truncated read must never be cached in place of"
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	f := NewWithRateLimit(0)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/page", nil)
+	resp, err := f.Do(req)
+	if err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+	// Read only a prefix, then close early - mirrors probe.go's
+	// io.LimitReader(resp.Body, 1024) followed by Close.
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(resp.Body, buf); err != nil {
+		t.Fatalf("failed to read prefix: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/page", nil)
+	resp, err = f.Do(req)
+	if err != nil {
+		t.Fatalf("second request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a real 200, not a replayed entry, got %d", resp.StatusCode)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read second response body: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("second response body: got %q (len %d), want the full %d-byte body - an early close must not poison the cache with a truncated prefix", got, len(got), len(body))
+	}
+}
+
+// TestHostLimiterSpacesRequests checks that two requests to the same host
+// are separated by at least the configured interval.
+func TestHostLimiterSpacesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	f := NewWithRateLimit(50 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/page", nil)
+		resp, err := f.Do(req)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected requests to be spaced by at least 50ms, took %v", elapsed)
+	}
+}
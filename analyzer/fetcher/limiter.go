@@ -0,0 +1,45 @@
+package fetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces out requests to the same host so a crawl can't hammer
+// one domain.
+type hostLimiter struct {
+	mu       sync.Mutex
+	lastHit  map[string]time.Time
+	interval time.Duration
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{lastHit: make(map[string]time.Time), interval: interval}
+}
+
+func (h *hostLimiter) wait(ctx context.Context, host string) error {
+	h.mu.Lock()
+	now := time.Now()
+	var delay time.Duration
+	if last, ok := h.lastHit[host]; ok {
+		if elapsed := now.Sub(last); elapsed < h.interval {
+			delay = h.interval - elapsed
+		}
+	}
+	h.lastHit[host] = now.Add(delay)
+	h.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
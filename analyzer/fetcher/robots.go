@@ -0,0 +1,145 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// robotsCache fetches and parses each host's robots.txt once and keeps the
+// result for the lifetime of its fetcher, keyed by scheme+host. Fetches are
+// not deduplicated: concurrent first requests to the same host may each
+// fetch it, and the last result stored is the one kept.
+type robotsCache struct {
+	mu    sync.Mutex
+	rules map[string]*robotRules
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{rules: make(map[string]*robotRules)}
+}
+
+func (c *robotsCache) allowed(ctx context.Context, client *http.Client, userAgent string, target *url.URL) (bool, error) {
+	key := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	rules, ok := c.rules[key]
+	c.mu.Unlock()
+
+	if !ok {
+		rules = fetchRobots(ctx, client, userAgent, target)
+		c.mu.Lock()
+		c.rules[key] = rules
+		c.mu.Unlock()
+	}
+
+	return rules.allows(target.Path), nil
+}
+
+// robotRules holds the Disallow prefixes that apply to us on one host. A
+// failed or missing robots.txt fetch yields an empty, permissive robotRules.
+type robotRules struct {
+	disallow []string
+}
+
+func fetchRobots(ctx context.Context, client *http.Client, userAgent string, target *url.URL) *robotRules {
+	robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return &robotRules{}
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &robotRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotRules{}
+	}
+
+	return parseRobots(resp.Body, userAgent)
+}
+
+// parseRobots reads a robots.txt body and returns the Disallow rules that
+// apply to userAgent, preferring a group addressed to us by name over the
+// wildcard "*" group.
+func parseRobots(r io.Reader, userAgent string) *robotRules {
+	token := productToken(userAgent)
+
+	var (
+		group    string
+		named    bool
+		specific robotRules
+		wildcard robotRules
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			group = value
+			if strings.EqualFold(group, token) {
+				named = true
+			}
+		case "disallow":
+			if value == "" {
+				continue
+			}
+			switch {
+			case strings.EqualFold(group, token):
+				specific.disallow = append(specific.disallow, value)
+			case group == "*":
+				wildcard.disallow = append(wildcard.disallow, value)
+			}
+		}
+	}
+
+	if named {
+		return &specific
+	}
+	return &wildcard
+}
+
+// productToken extracts the short product name robots.txt groups actually
+// address (e.g. "urlscraper" out of "urlscraper/1.0 (+https://...)") - real
+// robots.txt files name crawlers by that token, never by the full User-Agent
+// header value.
+func productToken(userAgent string) string {
+	token := userAgent
+	if i := strings.IndexAny(token, " /"); i >= 0 {
+		token = token[:i]
+	}
+	return token
+}
+
+func (r *robotRules) allows(path string) bool {
+	if r == nil {
+		return true
+	}
+	for _, prefix := range r.disallow {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
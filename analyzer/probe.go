@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"urlscraper/analyzer/fetcher"
+)
+
+// Tuning parameters for the link accessibility probe. The timeouts are vars,
+// not consts, so tests can shrink them instead of waiting out the real
+// deadlines against httptest servers.
+const probeWorkers = 10
+
+var (
+	probeRequestTimeout  = 5 * time.Second
+	probeOverallDeadline = 20 * time.Second
+)
+
+// probeResult is one link's outcome from probeLink: at most one of
+// inaccessible/skipped is set, both nil if the link checked out fine.
+type probeResult struct {
+	inaccessible *LinkStatus
+	skipped      *LinkStatus
+}
+
+// probeLinkAccessibility dispatches bounded HEAD requests (with a ranged GET
+// fallback) against every link through f - the same fetcher.Fetcher pages
+// are fetched through - so probing honors robots.txt and the per-host rate
+// limit instead of hammering a target with its own separate schedule. It
+// reports the links that don't resolve within the overall deadline as
+// inaccessible, and separately reports links robots.txt told us not to check
+// at all - those aren't broken, just untested, and callers shouldn't count
+// them as the same thing.
+func probeLinkAccessibility(ctx context.Context, f fetcher.Fetcher, links []string) (inaccessible, skipped []LinkStatus) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeOverallDeadline)
+	defer cancel()
+
+	workers := probeWorkers
+	if workers > len(links) {
+		workers = len(links)
+	}
+
+	jobs := make(chan string)
+	results := make(chan probeResult, len(links))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				results <- probeLink(ctx, f, link)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for i, link := range links {
+			select {
+			case jobs <- link:
+			case <-ctx.Done():
+				// Links never handed to a worker are reported too, rather
+				// than dropped from the result.
+				for _, missed := range links[i:] {
+					results <- probeResult{inaccessible: &LinkStatus{URL: missed, Reason: fmt.Sprintf("not probed: %v", ctx.Err())}}
+				}
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if result.inaccessible != nil {
+			inaccessible = append(inaccessible, *result.inaccessible)
+		}
+		if result.skipped != nil {
+			skipped = append(skipped, *result.skipped)
+		}
+	}
+	return inaccessible, skipped
+}
+
+// probeLink checks a single link through f, falling back to a ranged GET
+// when the server refuses HEAD requests outright. f's own robots.txt check
+// and per-host rate limit apply to every probe request just as they do to
+// page fetches. A robots.txt disallow means the link was never actually
+// checked, so it's reported as skipped rather than inaccessible.
+func probeLink(ctx context.Context, f fetcher.Fetcher, link string) probeResult {
+	reqCtx, cancel := context.WithTimeout(ctx, probeRequestTimeout)
+	defer cancel()
+
+	status, err := doProbeRequest(reqCtx, f, http.MethodHead, link, false)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = doProbeRequest(reqCtx, f, http.MethodGet, link, true)
+	}
+
+	switch {
+	case errors.Is(err, fetcher.ErrDisallowed):
+		return probeResult{skipped: &LinkStatus{URL: link, Reason: "disallowed by robots.txt"}}
+	case err != nil:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return probeResult{inaccessible: &LinkStatus{URL: link, Reason: "request timed out"}}
+		}
+		return probeResult{inaccessible: &LinkStatus{URL: link, Reason: fmt.Sprintf("request failed: %v", err)}}
+	case status >= 400:
+		return probeResult{inaccessible: &LinkStatus{URL: link, Reason: fmt.Sprintf("HTTP %d: %s", status, http.StatusText(status))}}
+	}
+	return probeResult{}
+}
+
+// doProbeRequest issues a single probe request through f and reports its
+// status code. When ranged is set it asks the server for only the first
+// byte, so the fallback GET stays as cheap as the HEAD it's replacing.
+func doProbeRequest(ctx context.Context, f fetcher.Fetcher, method, link string, ranged bool) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	if ranged {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := f.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	return resp.StatusCode, nil
+}
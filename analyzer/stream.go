@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// Tuning parameters for fetchPage. maxBodyBytes bounds memory regardless of
+// how large a page claims to be; doctypeSniffLen is enough to see a DOCTYPE
+// declaration without buffering the rest of the document; fetchTimeout is
+// the overall budget for a single fetch-and-parse, covering both a slow
+// connection and a slow-loris response that trickles bytes forever.
+const (
+	maxBodyBytes    = 10 * 1024 * 1024
+	doctypeSniffLen = 1024
+	fetchTimeout    = 30 * time.Second
+)
+
+// ctxReader aborts Read as soon as ctx is done, so a response that stops
+// sending bytes mid-body (rather than failing outright) can't stall the
+// parser past the fetch deadline.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	select {
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	default:
+	}
+	return c.r.Read(p)
+}
+
+// fetchPage retrieves urlStr and streams it straight into a goquery
+// document instead of buffering the whole body first. The DOCTYPE is
+// sniffed from the first kilobyte so detectHTMLVersion no longer needs the
+// full body, and the body is decoded according to its declared charset (HTTP
+// header or <meta charset>) so non-UTF-8 pages don't come out as mojibake.
+func fetchPage(ctx context.Context, urlStr string) (doc *goquery.Document, htmlVersion string, status int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("Failed to fetch URL: %v", err)
+	}
+
+	resp, err := httpFetcher.Do(req)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("Failed to fetch URL: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, "", resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	limited := io.LimitReader(ctxReader{ctx: ctx, r: resp.Body}, maxBodyBytes)
+	buffered := bufio.NewReaderSize(limited, doctypeSniffLen)
+
+	sniff, _ := buffered.Peek(doctypeSniffLen)
+	htmlVersion = detectHTMLVersion(string(sniff))
+
+	decoded, err := charset.NewReader(buffered, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, "", resp.StatusCode, fmt.Errorf("Failed to detect charset: %v", err)
+	}
+
+	doc, err = goquery.NewDocumentFromReader(decoded)
+	if err != nil {
+		return nil, "", resp.StatusCode, fmt.Errorf("Failed to parse HTML: %v", err)
+	}
+
+	return doc, htmlVersion, resp.StatusCode, nil
+}
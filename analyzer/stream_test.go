@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestFetchPageDecodesDeclaredCharset checks that a non-UTF-8 page declared
+// via its Content-Type header comes out correctly decoded instead of as
+// mojibake.
+func TestFetchPageDecodesDeclaredCharset(t *testing.T) {
+	body := []byte("<!DOCTYPE html><html><head><title>resum\xE9</title></head></html>")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	result := AnalyzeURL(context.Background(), server.URL)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Title != "resumé" {
+		t.Errorf("title: got %q, want %q", result.Title, "resumé")
+	}
+}
+
+// TestFetchPageSniffsDoctypeOnLargePage checks that the DOCTYPE, which only
+// needs the first kilobyte, is still detected correctly once the rest of a
+// much larger document streams in behind it.
+func TestFetchPageSniffsDoctypeOnLargePage(t *testing.T) {
+	var body strings.Builder
+	body.WriteString("<!DOCTYPE html><html><head><title>Big</title></head><body>")
+	for i := 0; i < 5000; i++ {
+		body.WriteString("<p>padding</p>")
+	}
+	body.WriteString("</body></html>")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.String()))
+	}))
+	defer server.Close()
+
+	result := AnalyzeURL(context.Background(), server.URL)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.HTMLVersion != "HTML5" {
+		t.Errorf("HTML version: got %q, want %q", result.HTMLVersion, "HTML5")
+	}
+	if result.Title != "Big" {
+		t.Errorf("title: got %q, want %q", result.Title, "Big")
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"urlscraper/analyzer"
+	"urlscraper/jobqueue"
+)
+
+// jobs holds every crawl job started through the JSON API. Single-page
+// analyses are cheap enough to run synchronously and never touch it.
+var jobs jobqueue.JobStore = jobqueue.NewMemoryStore()
+
+// crawlRequest mirrors analyzer.CrawlOptions for JSON requests; zero values
+// fall back to CrawlSite's own defaults.
+type crawlRequest struct {
+	MaxDepth     int  `json:"max_depth"`
+	MaxPages     int  `json:"max_pages"`
+	SameHostOnly bool `json:"same_host_only"`
+	Concurrency  int  `json:"concurrency"`
+}
+
+type analyzeRequest struct {
+	URL   string        `json:"url"`
+	Crawl *crawlRequest `json:"crawl"`
+}
+
+// apiAnalyzeHandler runs the same core analysis/crawl used by the HTML form
+// handlers, but over JSON. A plain analyze request runs synchronously and
+// returns a PageAnalysis; a crawl request is handed to a background job and
+// returns its job ID for polling or streaming.
+func apiAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req analyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Crawl == nil {
+		writeJSON(w, http.StatusOK, analyzeURL(r.Context(), req.URL))
+		return
+	}
+
+	job := startCrawlJob(req.URL, req.Crawl.toCrawlOptions())
+	writeJSON(w, http.StatusAccepted, map[string]string{"job_id": job.ID})
+}
+
+// apiJobsHandler dispatches GET /api/v1/jobs/{id} and
+// GET /api/v1/jobs/{id}/stream, since the stdlib mux used by main() doesn't
+// do path-parameter matching.
+func apiJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")
+	if rest, ok := strings.CutSuffix(id, "/stream"); ok {
+		apiJobStream(w, r, rest)
+		return
+	}
+	apiJobStatus(w, r, id)
+}
+
+func apiJobStatus(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := jobs.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// apiJobStream pushes job snapshots over Server-Sent Events as the crawl
+// progresses, closing the stream once the job reaches a terminal state.
+func apiJobStream(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := jobs.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	updates, unsubscribe, ok := jobs.Subscribe(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	writeEvent(w, job)
+	flusher.Flush()
+	if isTerminal(job.Status) {
+		return
+	}
+
+	for {
+		select {
+		case job, ok := <-updates:
+			if !ok {
+				return
+			}
+			writeEvent(w, job)
+			flusher.Flush()
+			if isTerminal(job.Status) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, job *jobqueue.Job) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", body)
+}
+
+func isTerminal(status jobqueue.Status) bool {
+	return status == jobqueue.StatusDone || status == jobqueue.StatusError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (c *crawlRequest) toCrawlOptions() analyzer.CrawlOptions {
+	return analyzer.CrawlOptions{
+		MaxDepth:     c.MaxDepth,
+		MaxPages:     c.MaxPages,
+		SameHostOnly: c.SameHostOnly,
+		Concurrency:  c.Concurrency,
+	}
+}
+
+// startCrawlJob enqueues opts against seedURL and runs the crawl in the
+// background, reporting progress through the job store as pages are
+// fetched. It deliberately uses context.Background() rather than the
+// triggering request's context: the job must keep running long after the
+// handler has already responded with a job ID.
+func startCrawlJob(seedURL string, opts analyzer.CrawlOptions) *jobqueue.Job {
+	job := jobs.Create()
+
+	opts.Progress = func(fetched, seen int) {
+		jobs.Update(job.ID, func(j *jobqueue.Job) {
+			j.PagesFetched = fetched
+			j.TotalQueued = seen
+		})
+	}
+
+	go func() {
+		jobs.Update(job.ID, func(j *jobqueue.Job) { j.Status = jobqueue.StatusRunning })
+		report := analyzer.CrawlSite(context.Background(), seedURL, opts)
+		jobs.Update(job.ID, func(j *jobqueue.Job) {
+			j.Status = jobqueue.StatusDone
+			j.Report = report
+		})
+	}()
+
+	return job
+}
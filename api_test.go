@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"urlscraper/jobqueue"
+)
+
+// TestApiAnalyzeHandlerSynchronous checks that a plain (non-crawl) request
+// runs inline and returns a PageAnalysis, not a job handle.
+func TestApiAnalyzeHandlerSynchronous(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		w.Write([]byte("<html><head><title>Test Page</title></head><body></body></html>"))
+	}))
+	defer server.Close()
+
+	body, _ := json.Marshal(analyzeRequest{URL: server.URL})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analyze", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	apiAnalyzeHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var analysis struct {
+		Title      string
+		StatusCode int
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &analysis); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if analysis.Title != "Test Page" {
+		t.Errorf("title: got %q, want %q", analysis.Title, "Test Page")
+	}
+	if analysis.StatusCode != http.StatusOK {
+		t.Errorf("status code: got %d, want %d", analysis.StatusCode, http.StatusOK)
+	}
+}
+
+// TestApiJobsHandlerPolling checks that a crawl request returns a job ID
+// that can be polled through to completion via apiJobsHandler.
+func TestApiJobsHandlerPolling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		w.Write([]byte("<html><body>Leaf page, no links</body></html>"))
+	}))
+	defer server.Close()
+
+	reqBody, _ := json.Marshal(analyzeRequest{URL: server.URL, Crawl: &crawlRequest{MaxDepth: 1, MaxPages: 5}})
+	startReq := httptest.NewRequest(http.MethodPost, "/api/v1/analyze", bytes.NewReader(reqBody))
+	startRec := httptest.NewRecorder()
+
+	apiAnalyzeHandler(startRec, startReq)
+
+	if startRec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want %d (body %s)", startRec.Code, http.StatusAccepted, startRec.Body.String())
+	}
+
+	var started struct {
+		JobID string `json:"job_id"`
+	}
+	if err := json.Unmarshal(startRec.Body.Bytes(), &started); err != nil {
+		t.Fatalf("failed to decode job start response: %v", err)
+	}
+	if started.JobID == "" {
+		t.Fatal("expected a non-empty job_id")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	var job jobqueue.Job
+	for {
+		pollReq := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+started.JobID, nil)
+		pollRec := httptest.NewRecorder()
+		apiJobsHandler(pollRec, pollReq)
+
+		if pollRec.Code != http.StatusOK {
+			t.Fatalf("poll status: got %d, want %d", pollRec.Code, http.StatusOK)
+		}
+		if err := json.Unmarshal(pollRec.Body.Bytes(), &job); err != nil {
+			t.Fatalf("failed to decode job: %v", err)
+		}
+		if isTerminal(job.Status) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for job %s to finish, last status %q", started.JobID, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if job.Status != jobqueue.StatusDone {
+		t.Fatalf("job status: got %q, want %q", job.Status, jobqueue.StatusDone)
+	}
+	if job.Report == nil || job.Report.TotalPages != 1 {
+		t.Errorf("expected a report with 1 crawled page, got %+v", job.Report)
+	}
+}
+
+// TestApiJobStreamSSE checks that apiJobStream pushes a snapshot per update
+// and closes the connection once the job reaches a terminal state.
+func TestApiJobStreamSSE(t *testing.T) {
+	job := jobs.Create()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID+"/stream", nil)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		apiJobStream(rec, req, job.ID)
+		close(done)
+	}()
+
+	// Give apiJobStream a moment to subscribe before publishing updates, so
+	// neither update races ahead of the subscription and gets dropped.
+	time.Sleep(20 * time.Millisecond)
+	jobs.Update(job.ID, func(j *jobqueue.Job) { j.Status = jobqueue.StatusRunning; j.PagesFetched = 1 })
+	jobs.Update(job.ID, func(j *jobqueue.Job) { j.Status = jobqueue.StatusDone; j.PagesFetched = 2 })
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the stream to close after the job finished")
+	}
+
+	body := rec.Body.String()
+	if got := strings.Count(body, "data: "); got < 2 {
+		t.Fatalf("expected at least 2 SSE events (initial + terminal), got %d in body %q", got, body)
+	}
+	if !strings.Contains(body, `"Status":"done"`) {
+		t.Errorf("expected the final event to report a done status, got %q", body)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"context"
+
+	"urlscraper/analyzer"
+)
+
+// analyzeURL is the single entry point every HTTP handler (HTML form and
+// JSON API) goes through for a single-page analysis, so the two surfaces
+// can't drift apart. ctx is tied to the originating request so the fetch
+// and link probe are cancelled if the client disconnects.
+func analyzeURL(ctx context.Context, urlStr string) *analyzer.PageAnalysis {
+	return analyzer.AnalyzeURL(ctx, urlStr)
+}
@@ -0,0 +1,15 @@
+package jobqueue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newID returns a random 32-character hex job ID.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("jobqueue: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
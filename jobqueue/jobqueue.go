@@ -0,0 +1,137 @@
+// Package jobqueue tracks long-running crawl jobs so the JSON API can hand
+// back a job ID immediately and let clients poll or subscribe for progress
+// instead of holding a request open for the whole crawl.
+package jobqueue
+
+import (
+	"sync"
+	"time"
+
+	"urlscraper/analyzer"
+)
+
+// Status is the lifecycle state of a Job.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusError   Status = "error"
+)
+
+// Job tracks the progress and outcome of one crawl request.
+type Job struct {
+	ID           string
+	Status       Status
+	PagesFetched int
+	TotalQueued  int
+	StartedAt    time.Time
+	Report       *analyzer.SiteReport
+	Error        string
+}
+
+// JobStore persists jobs and fans out progress updates. The in-memory
+// implementation below is the default; a Redis-backed store could satisfy
+// the same interface for multi-instance deployments.
+type JobStore interface {
+	// Create allocates a new job in the queued state.
+	Create() *Job
+	// Get returns a point-in-time snapshot of a job, if it exists. The
+	// returned *Job is the caller's own copy and is never mutated further.
+	Get(id string) (*Job, bool)
+	// Update applies fn to the job under lock and notifies subscribers.
+	Update(id string, fn func(*Job))
+	// Subscribe returns a channel of job snapshots pushed on every Update,
+	// plus an unsubscribe func the caller must invoke when done listening.
+	// An update that arrives while the channel's buffer is full is dropped
+	// for that subscriber. The bool return is false if the job doesn't exist.
+	Subscribe(id string) (<-chan *Job, func(), bool)
+}
+
+// MemoryStore is the in-process JobStore used when no external store is
+// configured. It's safe for concurrent use.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+	subs map[string][]chan *Job
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: make(map[string]*Job),
+		subs: make(map[string][]chan *Job),
+	}
+}
+
+func (m *MemoryStore) Create() *Job {
+	job := &Job{ID: newID(), Status: StatusQueued, StartedAt: time.Now()}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	return job
+}
+
+func (m *MemoryStore) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+func (m *MemoryStore) Update(id string, fn func(*Job)) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	fn(job)
+	snapshot := *job
+
+	// Sends happen under the lock so an unsubscribe can't close a channel
+	// mid-send; they never block, so holding the lock here is cheap.
+	for _, ch := range m.subs[id] {
+		select {
+		case ch <- &snapshot:
+		default:
+			// Slow subscriber: drop the update rather than block the job.
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *MemoryStore) Subscribe(id string) (<-chan *Job, func(), bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.jobs[id]; !ok {
+		return nil, nil, false
+	}
+
+	ch := make(chan *Job, 8)
+	m.subs[id] = append(m.subs[id], ch)
+
+	unsubscribe := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		subs := m.subs[id]
+		for i, c := range subs {
+			if c == ch {
+				m.subs[id] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe, true
+}
@@ -0,0 +1,59 @@
+package jobqueue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreCreateGet(t *testing.T) {
+	store := NewMemoryStore()
+
+	job := store.Create()
+	if job.Status != StatusQueued {
+		t.Errorf("status: got %q, want %q", job.Status, StatusQueued)
+	}
+
+	got, ok := store.Get(job.ID)
+	if !ok {
+		t.Fatalf("expected job %q to exist", job.ID)
+	}
+	if got.ID != job.ID {
+		t.Errorf("id: got %q, want %q", got.ID, job.ID)
+	}
+
+	if _, ok := store.Get("does-not-exist"); ok {
+		t.Errorf("expected lookup of unknown id to fail")
+	}
+}
+
+func TestMemoryStoreUpdateNotifiesSubscribers(t *testing.T) {
+	store := NewMemoryStore()
+	job := store.Create()
+
+	updates, unsubscribe, ok := store.Subscribe(job.ID)
+	if !ok {
+		t.Fatalf("expected subscribe to succeed for a known job")
+	}
+	defer unsubscribe()
+
+	store.Update(job.ID, func(j *Job) {
+		j.Status = StatusRunning
+		j.PagesFetched = 1
+	})
+
+	select {
+	case snapshot := <-updates:
+		if snapshot.Status != StatusRunning || snapshot.PagesFetched != 1 {
+			t.Errorf("got %+v, want status=%q pagesFetched=1", snapshot, StatusRunning)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
+
+func TestMemoryStoreSubscribeUnknownJob(t *testing.T) {
+	store := NewMemoryStore()
+	if _, _, ok := store.Subscribe("does-not-exist"); ok {
+		t.Errorf("expected subscribe to fail for an unknown job")
+	}
+}
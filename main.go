@@ -1,9 +1,10 @@
 package main
 
 import (
+	"html/template"
 	"log"
 	"net/http"
-	"text/template"
+	"strconv"
 	"urlscraper/analyzer"
 )
 
@@ -20,6 +21,9 @@ func init() {
 func main() {
 	http.HandleFunc("/", homeHandler)
 	http.HandleFunc("/analyze", analyzeHandler)
+	http.HandleFunc("/crawl", crawlHandler)
+	http.HandleFunc("/api/v1/analyze", apiAnalyzeHandler)
+	http.HandleFunc("/api/v1/jobs/", apiJobsHandler)
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 
 	log.Println("Starting server on http://localhost:8080")
@@ -55,8 +59,34 @@ func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	analysis := analyzer.AnalyzeURL(urlStr)
+	analysis := analyzeURL(r.Context(), urlStr)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	templates.ExecuteTemplate(w, "results.html", analysis)
 }
+
+func crawlHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlStr := r.FormValue("url")
+	if urlStr == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+
+	opts := analyzer.CrawlOptions{SameHostOnly: true}
+	if depth, err := strconv.Atoi(r.FormValue("depth")); err == nil {
+		opts.MaxDepth = depth
+	}
+	if maxPages, err := strconv.Atoi(r.FormValue("max_pages")); err == nil {
+		opts.MaxPages = maxPages
+	}
+
+	report := analyzer.CrawlSite(r.Context(), urlStr, opts)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	templates.ExecuteTemplate(w, "crawl.html", report)
+}